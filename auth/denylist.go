@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Denylist records revoked token IDs so logged-out or rotated tokens are
+// rejected even while still unexpired.
+type Denylist interface {
+	Revoke(tokenID string, expiresAt time.Time) error
+	IsRevoked(tokenID string) bool
+}
+
+// FileDenylist persists revoked token IDs to a JSON file, so a logout
+// survives a process restart. Expired entries are pruned opportunistically
+// on load and on revoke.
+type FileDenylist struct {
+	path string
+
+	mu      sync.Mutex
+	revoked map[string]time.Time // tokenID -> expiresAt
+}
+
+// NewFileDenylist builds a FileDenylist backed by path, loading any
+// previously revoked tokens from it if it exists.
+func NewFileDenylist(path string) (*FileDenylist, error) {
+	d := &FileDenylist{path: path, revoked: make(map[string]time.Time)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &d.revoked); err != nil {
+		return nil, err
+	}
+	d.pruneLocked()
+	return d, nil
+}
+
+// Revoke marks tokenID as revoked until expiresAt and flushes to disk.
+func (d *FileDenylist) Revoke(tokenID string, expiresAt time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.revoked[tokenID] = expiresAt
+	d.pruneLocked()
+	return d.saveLocked()
+}
+
+// IsRevoked reports whether tokenID has been revoked and hasn't expired.
+func (d *FileDenylist) IsRevoked(tokenID string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	expiresAt, ok := d.revoked[tokenID]
+	if !ok {
+		return false
+	}
+	return time.Now().Before(expiresAt)
+}
+
+func (d *FileDenylist) pruneLocked() {
+	now := time.Now()
+	for id, expiresAt := range d.revoked {
+		if now.After(expiresAt) {
+			delete(d.revoked, id)
+		}
+	}
+}
+
+func (d *FileDenylist) saveLocked() error {
+	if err := os.MkdirAll(filepath.Dir(d.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(d.revoked)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(d.path, data, 0o644)
+}
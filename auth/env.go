@@ -0,0 +1,7 @@
+package auth
+
+import "os"
+
+func getenv(key string) string {
+	return os.Getenv(key)
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Service bundles the pieces /auth/login, /auth/refresh, and /auth/logout
+// need: a TokenManager to mint tokens, a UserStore to check credentials
+// against, and a Denylist to revoke them.
+type Service struct {
+	Tokens   *TokenManager
+	Users    UserStore
+	Denylist Denylist
+}
+
+// NewService builds an auth Service.
+func NewService(tokens *TokenManager, users UserStore, denylist Denylist) *Service {
+	return &Service{Tokens: tokens, Users: users, Denylist: denylist}
+}
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type tokenResponse struct {
+	Success      bool   `json:"success"`
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	ExpiresIn    int    `json:"expiresIn"`
+}
+
+type errorResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// LoginHandler authenticates username+password against the configured
+// UserStore and, on success, issues an access and refresh token pair.
+func (s *Service) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{false, "Invalid request body"})
+		return
+	}
+
+	userID, scopes, ok := s.Users.Authenticate(req.Username, req.Password)
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, errorResponse{false, "Invalid username or password"})
+		return
+	}
+
+	accessID, err := newTokenID()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{false, "Failed to issue token"})
+		return
+	}
+	accessToken, err := s.Tokens.Issue(userID, scopes, "access", accessID, AccessTokenTTL)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{false, "Failed to issue token"})
+		return
+	}
+
+	refreshID, err := newTokenID()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{false, "Failed to issue token"})
+		return
+	}
+	refreshToken, err := s.Tokens.Issue(userID, scopes, "refresh", refreshID, RefreshTokenTTL)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{false, "Failed to issue token"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokenResponse{
+		Success:      true,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(AccessTokenTTL.Seconds()),
+	})
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// RefreshHandler exchanges a still-valid, unrevoked refresh token for a new
+// access token.
+func (s *Service) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{false, "Invalid request body"})
+		return
+	}
+
+	claims, err := s.Tokens.Parse(req.RefreshToken)
+	if err != nil || claims.TokenType != "refresh" {
+		writeJSON(w, http.StatusUnauthorized, errorResponse{false, "Invalid or expired refresh token"})
+		return
+	}
+
+	if s.Denylist.IsRevoked(claims.ID) {
+		writeJSON(w, http.StatusUnauthorized, errorResponse{false, "Refresh token has been revoked"})
+		return
+	}
+
+	accessID, err := newTokenID()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{false, "Failed to issue token"})
+		return
+	}
+	accessToken, err := s.Tokens.Issue(claims.Subject, claims.Scopes, "access", accessID, AccessTokenTTL)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{false, "Failed to issue token"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokenResponse{
+		Success:     true,
+		AccessToken: accessToken,
+		ExpiresIn:   int(AccessTokenTTL.Seconds()),
+	})
+}
+
+// LogoutHandler revokes the bearer token presented in the Authorization
+// header, so it's rejected by Middleware even though it hasn't expired yet.
+func (s *Service) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{false, "Missing or malformed Authorization header"})
+		return
+	}
+
+	claims, err := s.Tokens.Parse(token)
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, errorResponse{false, "Invalid or expired token"})
+		return
+	}
+
+	if err := s.Denylist.Revoke(claims.ID, claims.ExpiresAt.Time); err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{false, "Failed to revoke token"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, errorResponse{true, "Logged out"})
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+)
+
+func writeAuthError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write([]byte(`{"success":false,"message":"` + message + `"}`))
+}
+
+// Middleware verifies the "Authorization: Bearer <token>" header of every
+// request, checking signature, exp/nbf/iss/aud, and the denylist, and
+// attaches the resulting Principal to the request context.
+func Middleware(tm *TokenManager, denylist Denylist) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok || token == "" {
+				writeAuthError(w, http.StatusUnauthorized, "Missing or malformed Authorization header")
+				return
+			}
+
+			claims, err := tm.Parse(token)
+			if err != nil {
+				writeAuthError(w, http.StatusUnauthorized, "Invalid or expired token")
+				return
+			}
+
+			if claims.TokenType != "" && claims.TokenType != "access" {
+				writeAuthError(w, http.StatusUnauthorized, "Token is not an access token")
+				return
+			}
+
+			if denylist.IsRevoked(claims.ID) {
+				writeAuthError(w, http.StatusUnauthorized, "Token has been revoked")
+				return
+			}
+
+			principal := &Principal{
+				UserID:  claims.Subject,
+				Scopes:  claims.Scopes,
+				TokenID: claims.ID,
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
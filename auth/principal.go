@@ -0,0 +1,59 @@
+// Package auth issues and validates the bearer tokens that replace the old
+// X-User-ID placeholder header, and the request-scoped Principal that
+// Middleware populates from them.
+package auth
+
+import (
+	"context"
+	"net/http"
+)
+
+// Principal is the authenticated identity attached to a request's context
+// by Middleware.
+type Principal struct {
+	UserID  string
+	Scopes  []string
+	TokenID string
+}
+
+// HasScope reports whether p was issued the given scope.
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey string
+
+const principalKey contextKey = "principal"
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// FromContext returns the Principal attached by Middleware, or nil if none
+// is present.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey).(*Principal)
+	return p
+}
+
+// RequireScope builds middleware that rejects requests whose Principal
+// lacks scope, e.g. RequireScope("files:write") in front of /push so
+// read-only tokens minted for CI pulls can't upload.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p := FromContext(r.Context())
+			if p == nil || !p.HasScope(scope) {
+				http.Error(w, `{"success":false,"message":"Insufficient scope"}`, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
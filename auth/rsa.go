@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// parseRSAPublicKeyPEM parses an RSA public key from PKIX ("BEGIN PUBLIC
+// KEY") or PKCS1 ("BEGIN RSA PUBLIC KEY") PEM, whichever an operator's IdP
+// hands out.
+func parseRSAPublicKeyPEM(pemBytes []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	if pub, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		rsaPub, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("PEM block does not contain an RSA public key")
+		}
+		return rsaPub, nil
+	}
+
+	return x509.ParsePKCS1PublicKey(block.Bytes)
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// AccessTokenTTL is how long a freshly issued access token is valid for.
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL is how long a refresh token is valid for.
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// Claims is the JWT payload this service issues and expects.
+type Claims struct {
+	jwt.RegisteredClaims
+	Scopes []string `json:"scopes,omitempty"`
+	// TokenType distinguishes access tokens from refresh tokens so one
+	// can't be replayed as the other.
+	TokenType string `json:"typ,omitempty"`
+}
+
+// TokenManager issues and verifies the JWTs Middleware and /auth/* rely
+// on. It supports two modes: HS256 with a locally held signing key (the
+// common case, and the only mode that can Issue tokens), or RS256
+// verification against an externally configured public key or JWKS
+// endpoint, for deployments that delegate login to an external IdP.
+type TokenManager struct {
+	hmacKey  []byte
+	rsaPub   *rsa.PublicKey
+	jwks     *jwksClient
+	issuer   string
+	audience string
+}
+
+// NewTokenManagerFromEnv builds a TokenManager from AUTH_SIGNING_KEY (HS256,
+// issuing enabled) or AUTH_RSA_PUBLIC_KEY / AUTH_JWKS_URL (RS256,
+// verify-only), plus optional AUTH_ISSUER and AUTH_AUDIENCE claims.
+func NewTokenManagerFromEnv() (*TokenManager, error) {
+	tm := &TokenManager{
+		issuer:   getenv("AUTH_ISSUER"),
+		audience: getenv("AUTH_AUDIENCE"),
+	}
+
+	if key := getenv("AUTH_SIGNING_KEY"); key != "" {
+		tm.hmacKey = []byte(key)
+		return tm, nil
+	}
+
+	if pem := getenv("AUTH_RSA_PUBLIC_KEY"); pem != "" {
+		pub, err := parseRSAPublicKeyPEM([]byte(pem))
+		if err != nil {
+			return nil, fmt.Errorf("parsing AUTH_RSA_PUBLIC_KEY: %w", err)
+		}
+		tm.rsaPub = pub
+		return tm, nil
+	}
+
+	if jwksURL := getenv("AUTH_JWKS_URL"); jwksURL != "" {
+		tm.jwks = newJWKSClient(jwksURL)
+		return tm, nil
+	}
+
+	return nil, fmt.Errorf("one of AUTH_SIGNING_KEY, AUTH_RSA_PUBLIC_KEY, or AUTH_JWKS_URL is required")
+}
+
+// canIssue reports whether this TokenManager can mint its own tokens, as
+// opposed to only verifying ones issued by an external IdP.
+func (tm *TokenManager) canIssue() bool {
+	return tm.hmacKey != nil
+}
+
+// Issue mints a signed token for userID with the given scopes, tokenType
+// ("access" or "refresh"), tokenID (used for denylisting), and ttl.
+func (tm *TokenManager) Issue(userID string, scopes []string, tokenType, tokenID string, ttl time.Duration) (string, error) {
+	if !tm.canIssue() {
+		return "", fmt.Errorf("this TokenManager is verify-only (no AUTH_SIGNING_KEY configured)")
+	}
+
+	now := time.Now()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			ID:        tokenID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Scopes:    scopes,
+		TokenType: tokenType,
+	}
+	if tm.issuer != "" {
+		claims.Issuer = tm.issuer
+	}
+	if tm.audience != "" {
+		claims.Audience = jwt.ClaimStrings{tm.audience}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(tm.hmacKey)
+}
+
+// Parse verifies signature, exp, nbf, iss, and aud, and returns the decoded
+// claims. It does not consult the denylist; callers combine it with one.
+func (tm *TokenManager) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+
+	parserOpts := []jwt.ParserOption{}
+	if tm.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(tm.issuer))
+	}
+	if tm.audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(tm.audience))
+	}
+
+	_, err := jwt.ParseWithClaims(tokenString, claims, tm.keyFunc, parserOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (tm *TokenManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case "HS256":
+		if tm.hmacKey == nil {
+			return nil, fmt.Errorf("token is HS256 but no AUTH_SIGNING_KEY is configured")
+		}
+		return tm.hmacKey, nil
+	case "RS256":
+		if tm.rsaPub != nil {
+			return tm.rsaPub, nil
+		}
+		if tm.jwks != nil {
+			kid, _ := token.Header["kid"].(string)
+			return tm.jwks.publicKey(kid)
+		}
+		return nil, fmt.Errorf("token is RS256 but no AUTH_RSA_PUBLIC_KEY or AUTH_JWKS_URL is configured")
+	default:
+		return nil, fmt.Errorf("unsupported signing method %q", token.Method.Alg())
+	}
+}
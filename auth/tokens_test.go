@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestTokenManagerIssueParseRoundTrip(t *testing.T) {
+	tm := &TokenManager{hmacKey: []byte("test-signing-key")}
+
+	token, err := tm.Issue("user-1", []string{"read", "write"}, "access", "tok-1", AccessTokenTTL)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	claims, err := tm.Parse(token)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if claims.Subject != "user-1" {
+		t.Errorf("Subject = %q, want %q", claims.Subject, "user-1")
+	}
+	if claims.ID != "tok-1" {
+		t.Errorf("ID = %q, want %q", claims.ID, "tok-1")
+	}
+	if claims.TokenType != "access" {
+		t.Errorf("TokenType = %q, want %q", claims.TokenType, "access")
+	}
+	if len(claims.Scopes) != 2 || claims.Scopes[0] != "read" || claims.Scopes[1] != "write" {
+		t.Errorf("Scopes = %v, want [read write]", claims.Scopes)
+	}
+}
+
+func TestTokenManagerParseExpired(t *testing.T) {
+	tm := &TokenManager{hmacKey: []byte("test-signing-key")}
+
+	token, err := tm.Issue("user-1", nil, "access", "tok-1", -time.Minute)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	if _, err := tm.Parse(token); err == nil {
+		t.Fatal("Parse succeeded on an expired token, want error")
+	}
+}
+
+func TestTokenManagerParseWrongKey(t *testing.T) {
+	issuer := &TokenManager{hmacKey: []byte("signing-key-a")}
+	verifier := &TokenManager{hmacKey: []byte("signing-key-b")}
+
+	token, err := issuer.Issue("user-1", nil, "access", "tok-1", AccessTokenTTL)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	if _, err := verifier.Parse(token); err == nil {
+		t.Fatal("Parse succeeded against the wrong signing key, want error")
+	}
+}
+
+func TestTokenManagerParseWrongAlg(t *testing.T) {
+	tm := &TokenManager{hmacKey: []byte("test-signing-key")}
+
+	now := time.Now()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-1",
+			ID:        "tok-1",
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+		},
+		TokenType: "access",
+	}
+
+	// Sign with HS384 instead of the HS256 this TokenManager expects; the
+	// signature itself is valid, it's just the wrong algorithm.
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS384, claims).SignedString(tm.hmacKey)
+	if err != nil {
+		t.Fatalf("signing with HS384: %v", err)
+	}
+
+	if _, err := tm.Parse(token); err == nil {
+		t.Fatal("Parse accepted an HS384 token from an HS256-only TokenManager, want error")
+	}
+}
+
+func TestTokenManagerIssueVerifyOnly(t *testing.T) {
+	tm := &TokenManager{rsaPub: nil, jwks: nil}
+
+	if _, err := tm.Issue("user-1", nil, "access", "tok-1", AccessTokenTTL); err == nil {
+		t.Fatal("Issue succeeded on a verify-only TokenManager, want error")
+	}
+}
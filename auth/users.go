@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UserStore authenticates a username/password pair and reports the backend
+// user ID and scopes to issue a token for. Implementations can be backed by
+// a database; EnvUserStore is the bootstrap version.
+type UserStore interface {
+	Authenticate(username, password string) (userID string, scopes []string, ok bool)
+}
+
+type envUser struct {
+	bcryptHash string
+	userID     string
+	scopes     []string
+}
+
+// EnvUserStore is a fixed, in-memory UserStore loaded from an env var. Like
+// s3compat.StaticCredentialStore, it's a placeholder until user management
+// is backed by a real database.
+type EnvUserStore struct {
+	users map[string]envUser
+}
+
+// NewEnvUserStoreFromEnv parses AUTH_USERS, a comma-separated list of
+// "username:bcryptHash:userID:scope1|scope2" entries, e.g.
+//
+//	AUTH_USERS="alice:$2a$10$...:user_alice:files:read|files:write,bob:$2a$10$...:user_bob:files:read"
+func NewEnvUserStoreFromEnv() *EnvUserStore {
+	store := &EnvUserStore{users: make(map[string]envUser)}
+
+	raw := getenv("AUTH_USERS")
+	if raw == "" {
+		return store
+	}
+
+	for _, entry := range strings.Split(raw, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 4)
+		if len(parts) != 4 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			continue
+		}
+		var scopes []string
+		if parts[3] != "" {
+			scopes = strings.Split(parts[3], "|")
+		}
+		store.users[parts[0]] = envUser{bcryptHash: parts[1], userID: parts[2], scopes: scopes}
+	}
+
+	return store
+}
+
+// Authenticate implements UserStore.
+func (s *EnvUserStore) Authenticate(username, password string) (string, []string, bool) {
+	user, ok := s.users[username]
+	if !ok {
+		return "", nil, false
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.bcryptHash), []byte(password)) != nil {
+		return "", nil, false
+	}
+	return user.userID, user.scopes, true
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,7 +14,9 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -20,13 +25,16 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/joho/godotenv"
+
+	"github.com/regysaputra/repoflow-backend/auth"
+	"github.com/regysaputra/repoflow-backend/s3compat"
+	"github.com/regysaputra/repoflow-backend/storage"
 )
 
 const port = ":8081"
 
 type FileHandler struct {
-	Client *s3.Client
-	Bucket string
+	Storage storage.Storage
 }
 
 type Response struct {
@@ -34,6 +42,30 @@ type Response struct {
 	Message string `json:"message"`
 }
 
+// NewStorage builds the Storage backend named by provider ("r2", "s3", or
+// "local"), reading that backend's own env vars. Unset credentials for
+// backends other than the selected one are no longer fatal, since each
+// constructor only looks at its own env vars.
+func NewStorage(ctx context.Context, provider string) (storage.Storage, error) {
+	switch provider {
+	case "", "r2":
+		return storage.NewR2Storage(ctx)
+	case "s3":
+		return storage.NewS3Storage(ctx)
+	case "local":
+		return storage.NewLocalFSStorage()
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_PROVIDER %q (want r2, s3, or local)", provider)
+	}
+}
+
+func getenvDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
 func main() {
 	// Load config from env
 	if _, err := os.Stat(".env"); err == nil {
@@ -41,57 +73,99 @@ func main() {
 		log.Println("Loaded .env file for local development")
 	}
 
+	store, err := NewStorage(context.Background(), os.Getenv("STORAGE_PROVIDER"))
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	handler := &FileHandler{
+		Storage: store,
+	}
+
+	router := chi.NewRouter()
+	router.Use(middleware.Recoverer)
+
+	router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		SendJSON(w, http.StatusOK, Response{true, "OK"})
+	})
+
+	tokens, err := auth.NewTokenManagerFromEnv()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	denylist, err := auth.NewFileDenylist(getenvDefault("AUTH_DENYLIST_PATH", "./data/auth-denylist.json"))
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	authService := auth.NewService(tokens, auth.NewEnvUserStoreFromEnv(), denylist)
+
+	router.Post("/auth/login", authService.LoginHandler)
+	router.Post("/auth/refresh", authService.RefreshHandler)
+	router.With(auth.Middleware(tokens, denylist)).Post("/auth/logout", authService.LogoutHandler)
+
+	router.Group(func(r chi.Router) {
+		r.Use(auth.Middleware(tokens, denylist))
+		r.With(auth.RequireScope("files:write")).Post("/push", handler.pushHandler)
+		r.With(auth.RequireScope("files:read")).Get("/pull", handler.pullHandler)
+		r.With(auth.RequireScope("files:read")).Get("/list", handler.listHandler)
+		r.With(auth.RequireScope("files:write")).Post("/push-dir", handler.pushDirHandler)
+		r.With(auth.RequireScope("files:read")).Get("/pull-dir", handler.pullDirHandler)
+		r.With(auth.RequireScope("files:read")).Get("/manifest", handler.manifestHandler)
+
+		if mp, ok := store.(storage.MultipartStorage); ok {
+			multipart := NewMultipartHandler(mp)
+			r.With(auth.RequireScope("files:write")).Post("/uploads", multipart.Create)
+			r.With(auth.RequireScope("files:write")).Put("/uploads/{id}", multipart.UploadPart)
+			r.With(auth.RequireScope("files:write")).Post("/uploads/{id}/complete", multipart.Complete)
+			r.With(auth.RequireScope("files:write")).Delete("/uploads/{id}", multipart.Abort)
+		}
+	})
+
+	// S3-compatible API: authenticated separately via SigV4, not bearer
+	// tokens, so it sits outside the auth.Middleware group. It always talks to R2
+	// directly (that's the keyspace it exposes), independent of which
+	// Storage backend /push et al. are wired up against.
+	if s3Handler, err := newS3CompatHandler(context.Background()); err != nil {
+		log.Printf("S3-compatible API disabled: %v", err)
+	} else {
+		router.Handle("/{bucket}", s3Handler)
+		router.Handle("/{bucket}/*", s3Handler)
+	}
+
+	log.Fatal(http.ListenAndServe(port, router))
+}
+
+// newS3CompatHandler builds the s3compat.Handler from the R2 env vars. It
+// returns an error instead of exiting so a deployment running on another
+// Storage backend can simply leave R2_* unset.
+func newS3CompatHandler(ctx context.Context) (*s3compat.Handler, error) {
 	accountID := os.Getenv("R2_ACCOUNT_ID")
 	accessKey := os.Getenv("R2_ACCESS_KEY")
 	secretKey := os.Getenv("R2_SECRET_KEY")
 	bucketName := os.Getenv("R2_BUCKET")
 
 	if accountID == "" || accessKey == "" || secretKey == "" || bucketName == "" {
-		log.Fatal("Error: R2_ACCOUNT_ID, R2_ACCESS_KEY, R2_SECRET_KEY, and R2_BUCKET are required")
+		return nil, fmt.Errorf("R2_ACCOUNT_ID, R2_ACCESS_KEY, R2_SECRET_KEY, and R2_BUCKET are required")
 	}
 
-	// 1. Load the Default Config (No resolver needed here anymore)
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
+	cfg, err := config.LoadDefaultConfig(ctx,
 		config.WithRegion("auto"),
 		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
 	)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	// 2. Create the Client with BaseEndpoint
-	// This replaces the deprecated EndpointResolverWithOptions
 	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
 		o.BaseEndpoint = aws.String(fmt.Sprintf("https://%s.r2.cloudflarestorage.com", accountID))
 	})
 
-	handler := &FileHandler{
-		Client: client,
-		Bucket: bucketName,
-	}
-
-	router := chi.NewRouter()
-	router.Use(middleware.Recoverer)
-
-	router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		SendJSON(w, http.StatusOK, Response{true, "OK"})
-	})
-
-	router.Group(func(r chi.Router) {
-		r.Use(AuthMiddleware)
-		r.Post("/push", handler.pushHandler)
-		r.Get("/pull", handler.pullHandler)
-		r.Get("/list", handler.listHandler)
-		r.Post("/push-dir", handler.pushDirHandler)
-		r.Get("/pull-dir", handler.pullDirHandler)
-	})
-
-	log.Fatal(http.ListenAndServe(port, router))
+	return s3compat.NewHandler(client, bucketName, s3compat.NewStaticCredentialStoreFromEnv()), nil
 }
 
 func (h *FileHandler) pushHandler(w http.ResponseWriter, r *http.Request) {
 	// Retrieve user ID from context
-	userID := r.Context().Value(userIDKey).(string)
+	userID := auth.FromContext(r.Context()).UserID
 
 	// Parse max 100MB
 	if err := r.ParseMultipartForm(100 << 20); err != nil {
@@ -108,31 +182,24 @@ func (h *FileHandler) pushHandler(w http.ResponseWriter, r *http.Request) {
 
 	key := fmt.Sprintf("%s/%s", userID, header.Filename)
 
-	// Upload to R2
-	_, err = h.Client.PutObject(r.Context(), &s3.PutObjectInput{
-		Bucket: aws.String(h.Bucket),
-		Key:    aws.String(key),
-		Body:   file,
-		// ContentLength is helpful for S3 to know size upfront
-		ContentLength: aws.Int64(header.Size),
-		Metadata: map[string]string{
-			"owner-id": userID,
-		},
+	// Upload to the configured storage backend
+	err = h.Storage.Put(r.Context(), key, file, header.Size, map[string]string{
+		"owner-id": userID,
 	})
 
 	if err != nil {
-		log.Printf("R2 Upload Error: %v", err)
-		SendJSON(w, http.StatusInternalServerError, Response{false, "Failed to upload to R2"})
+		log.Printf("Storage upload error: %v", err)
+		SendJSON(w, http.StatusInternalServerError, Response{false, "Failed to upload file"})
 		return
 	}
 
-	log.Printf("File uploaded to R2: %s", key)
+	log.Printf("File uploaded: %s", key)
 	SendJSON(w, http.StatusOK, Response{true, fmt.Sprintf("File '%s' uploaded successfully", key)})
 }
 
 func (h *FileHandler) pullHandler(w http.ResponseWriter, r *http.Request) {
 	// Retrieve user ID from context
-	userID := r.Context().Value(userIDKey).(string)
+	userID := auth.FromContext(r.Context()).UserID
 
 	filename := r.URL.Query().Get("file")
 	if filename == "" {
@@ -142,11 +209,34 @@ func (h *FileHandler) pullHandler(w http.ResponseWriter, r *http.Request) {
 
 	key := fmt.Sprintf("%s/%s", userID, filename)
 
-	// Request object from R2
-	output, err := h.Client.GetObject(r.Context(), &s3.GetObjectInput{
-		Bucket: aws.String(h.Bucket),
-		Key:    aws.String(key),
-	})
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filepath.Base(filename)))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Accept-Ranges", "bytes")
+
+	if rangeSpec := r.Header.Get("Range"); rangeSpec != "" {
+		if rs, ok := h.Storage.(storage.RangeStorage); ok {
+			body, contentRange, err := rs.GetRange(r.Context(), key, rangeSpec)
+			if err != nil {
+				log.Printf("Range download error: %v", err)
+				SendJSON(w, http.StatusRequestedRangeNotSatisfiable, Response{false, "Invalid range"})
+				return
+			}
+			defer body.Close()
+
+			w.Header().Set("Content-Range", contentRange)
+			if length, ok := rangeContentLength(contentRange); ok {
+				w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+			}
+			w.WriteHeader(http.StatusPartialContent)
+			if _, err := io.Copy(w, body); err != nil {
+				log.Printf("Stream error: %v", err)
+			}
+			return
+		}
+	}
+
+	// Request object from the configured storage backend
+	body, size, err := h.Storage.Get(r.Context(), key)
 
 	if err != nil {
 		// Differentiate between "Not Found" and other errors if needed
@@ -154,57 +244,139 @@ func (h *FileHandler) pullHandler(w http.ResponseWriter, r *http.Request) {
 		SendJSON(w, http.StatusNotFound, Response{false, "File not found or access denied"})
 		return
 	}
-	defer output.Body.Close()
+	defer body.Close()
 
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filepath.Base(filename)))
-	w.Header().Set("Content-Type", "application/octet-stream")
-	if output.ContentLength != nil {
-		w.Header().Set("Content-Length", fmt.Sprintf("%d", *output.ContentLength))
-	}
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", size))
 
-	// Stream R2 -> User
-	if _, err := io.Copy(w, output.Body); err != nil {
+	// Stream storage -> User
+	if _, err := io.Copy(w, body); err != nil {
 		log.Printf("Stream error: %v", err)
 	}
 }
 
+// rangeContentLength derives the Content-Length for a partial response from
+// a "bytes start-end/size" Content-Range header value.
+func rangeContentLength(contentRange string) (int64, bool) {
+	spec := strings.TrimPrefix(contentRange, "bytes ")
+	if spec == contentRange {
+		return 0, false
+	}
+	spec = strings.SplitN(spec, "/", 2)[0]
+
+	parts := strings.SplitN(spec, "-", 2)
+	if len(parts) != 2 {
+		return 0, false
+	}
+
+	start, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	end, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return end - start + 1, true
+}
+
+type listedFile struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// listHandler handles GET /list?prefix=&delimiter=&max-keys=&continuation-token=,
+// mirroring S3's ListObjectsV2 semantics scoped to the caller's own keyspace.
+// A delimiter (typically "/") collapses everything after it into
+// commonPrefixes instead of individual files, so clients can browse one
+// "directory" level at a time instead of always receiving a flat list.
 func (h *FileHandler) listHandler(w http.ResponseWriter, r *http.Request) {
 	// Retrieve user ID from context
-	userID := r.Context().Value(userIDKey).(string)
+	userID := auth.FromContext(r.Context()).UserID
 	userPrefix := userID + "/"
 
-	// List objects in R2
-	output, err := h.Client.ListObjectsV2(r.Context(), &s3.ListObjectsV2Input{
-		Bucket: aws.String(h.Bucket),
-		Prefix: aws.String(userPrefix),
-	})
+	query := r.URL.Query()
+	requestPrefix := query.Get("prefix")
+	prefix := userPrefix + requestPrefix
+	delimiter := query.Get("delimiter")
+	continuationToken := query.Get("continuation-token")
+
+	maxKeys := 1000
+	if raw := query.Get("max-keys"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			SendJSON(w, http.StatusBadRequest, Response{false, "max-keys must be a positive integer"})
+			return
+		}
+		maxKeys = n
+	}
 
+	objects, nextMarker, err := h.Storage.List(r.Context(), prefix, continuationToken, maxKeys)
 	if err != nil {
 		log.Printf("List error: %v", err)
 		SendJSON(w, http.StatusInternalServerError, Response{false, "Failed to list files"})
 		return
 	}
 
-	var fileList []string
-	for _, obj := range output.Contents {
-		cleanName := strings.TrimPrefix(*obj.Key, userPrefix)
+	var files []listedFile
+	var commonPrefixes []string
+	seenPrefixes := map[string]bool{}
 
+	for _, obj := range objects {
+		cleanName := strings.TrimPrefix(obj.Key, userPrefix)
 		if cleanName == "" {
 			continue
 		}
 
-		fileList = append(fileList, cleanName)
+		if delimiter != "" {
+			// Only search for the delimiter in what's below the caller's
+			// requested prefix, not the whole user-scoped key, so browsing
+			// below the root groups by the next path segment instead of
+			// collapsing everything into the requested prefix itself.
+			remainder := strings.TrimPrefix(obj.Key, prefix)
+			if idx := strings.Index(remainder, delimiter); idx >= 0 {
+				commonPrefix := requestPrefix + remainder[:idx+len(delimiter)]
+				if !seenPrefixes[commonPrefix] {
+					seenPrefixes[commonPrefix] = true
+					commonPrefixes = append(commonPrefixes, commonPrefix)
+				}
+				continue
+			}
+		}
+
+		files = append(files, listedFile{
+			Key:          cleanName,
+			Size:         obj.Size,
+			ETag:         obj.ETag,
+			LastModified: obj.LastModified,
+		})
 	}
 
 	SendJSON(w, http.StatusOK, map[string]interface{}{
-		"success": true,
-		"files":   fileList,
+		"success":               true,
+		"files":                 files,
+		"commonPrefixes":        commonPrefixes,
+		"isTruncated":           nextMarker != "",
+		"nextContinuationToken": nextMarker,
 	})
 }
 
 func (h *FileHandler) pushDirHandler(w http.ResponseWriter, r *http.Request) {
 	// Retrieve user ID from context
-	userID := r.Context().Value(userIDKey).(string)
+	userID := auth.FromContext(r.Context()).UserID
+
+	incremental := r.URL.Query().Get("mode") == "incremental"
+	basePrefix := dirPrefix(userID, r.URL.Query().Get("name"))
+
+	// Incremental push-dir is two phases on the same endpoint: a JSON-bodied
+	// negotiation that returns the files the server needs, followed by a
+	// second call (handled below) that actually uploads a tarball of them.
+	if incremental && strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		h.negotiatePushDir(w, r, basePrefix)
+		return
+	}
 
 	// Parse max 500MB
 	if err := r.ParseMultipartForm(500 << 20); err != nil {
@@ -219,24 +391,13 @@ func (h *FileHandler) pushDirHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	// Determine the base prefix
-	basePrefix := userID + "/"
-
-	// Optional prefix from query param
-	subDir := r.URL.Query().Get("name")
-	if subDir != "" {
-		// Clean the input to prevent ".." attacks, then ensure trailing slash
-		cleanSub := filepath.Clean(subDir)
-		if cleanSub == "." || cleanSub == "/" {
-			cleanSub = ""
-		} else {
-			// Ensure we don't start with a slash (to avoid double //)
-			cleanSub = strings.TrimPrefix(cleanSub, "/")
-			// Ensure we end with a slash
-			if !strings.HasSuffix(cleanSub, "/") {
-				cleanSub += "/"
-			}
-			basePrefix += cleanSub
+	var m *manifest
+	if incremental {
+		m, err = loadManifest(r.Context(), h.Storage, basePrefix)
+		if err != nil {
+			log.Printf("Failed to load manifest for %s: %v", basePrefix, err)
+			SendJSON(w, http.StatusInternalServerError, Response{false, "Failed to load manifest"})
+			return
 		}
 	}
 
@@ -273,32 +434,56 @@ func (h *FileHandler) pushDirHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		objectKey := basePrefix + cleanName
 
-		// Upload individual file from the tar stream directly to R2
-		// Note: header.Size is crucial here so S3 client doesn't need to buffer the stream
-		_, err = h.Client.PutObject(r.Context(), &s3.PutObjectInput{
-			Bucket:        aws.String(h.Bucket),
-			Key:           aws.String(objectKey),
-			Body:          tr, // tar reader acts as an io.Reader for the current file
-			ContentLength: aws.Int64(header.Size),
-			Metadata: map[string]string{
+		if !incremental {
+			// Upload individual file from the tar stream directly to storage
+			// Note: header.Size is crucial here so the backend doesn't need to buffer the stream
+			if err := h.Storage.Put(r.Context(), objectKey, tr, header.Size, map[string]string{
 				"owner-id": userID,
-			},
-		})
+			}); err != nil {
+				log.Printf("Failed to upload part of dir: %s. Error: %v", objectKey, err)
+				continue // Strategy: Log and continue, or fail hard?
+			}
+			fileCount++
+			continue
+		}
 
+		// Incremental mode needs the sha256 before it can write the object's
+		// metadata and the manifest entry, so buffer the entry instead of
+		// streaming it straight through.
+		data, err := io.ReadAll(tr)
 		if err != nil {
+			log.Printf("Failed to read part of dir: %s. Error: %v", objectKey, err)
+			continue
+		}
+		sum := sha256.Sum256(data)
+		hexSum := hex.EncodeToString(sum[:])
+
+		if err := h.Storage.Put(r.Context(), objectKey, bytes.NewReader(data), int64(len(data)), map[string]string{
+			"owner-id": userID,
+			"sha256":   hexSum,
+		}); err != nil {
 			log.Printf("Failed to upload part of dir: %s. Error: %v", objectKey, err)
-			continue // Strategy: Log and continue, or fail hard?
+			continue
+		}
+
+		m.Files[cleanName] = manifestEntry{Size: int64(len(data)), SHA256: hexSum}
+		if err := saveManifest(r.Context(), h.Storage, basePrefix, m); err != nil {
+			log.Printf("Failed to update manifest for %s: %v", basePrefix, err)
 		}
 
 		fileCount++
 	}
 
+	if incremental && r.URL.Query().Get("prune") == "true" {
+		h.pruneDir(r.Context(), basePrefix, m)
+	}
+
 	log.Printf("Directory upload complete. Processed %d files.", fileCount)
-	SendJSON(w, http.StatusOK, Response{true, fmt.Sprintf("Extracted and uploaded %d files to %s", fileCount, h.Bucket)})
+	SendJSON(w, http.StatusOK, Response{true, fmt.Sprintf("Extracted and uploaded %d files", fileCount)})
 }
 
 func (h *FileHandler) pullDirHandler(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value(userIDKey).(string)
+	userID := auth.FromContext(r.Context()).UserID
 
 	dirName := r.URL.Query().Get("dir")
 	if dirName == "" {
@@ -329,62 +514,61 @@ func (h *FileHandler) pullDirHandler(w http.ResponseWriter, r *http.Request) {
 	defer tw.Close()
 
 	// 4. List Objects (Handle Pagination for large folders)
-	paginator := s3.NewListObjectsV2Paginator(h.Client, &s3.ListObjectsV2Input{
-		Bucket: aws.String(h.Bucket),
-		Prefix: aws.String(prefix),
-	})
-
 	fileCount := 0
+	marker := ""
 
-	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(r.Context())
+	for {
+		objects, nextMarker, err := h.Storage.List(r.Context(), prefix, marker, 1000)
 		if err != nil {
 			log.Printf("Failed to list objects: %v", err)
 			return // Cannot write JSON error because headers are already sent
 		}
 
-		for _, obj := range page.Contents {
+		for _, obj := range objects {
+			key := obj.Key
 			// Skip if it's the folder itself (0 byte object ending in /)
-			if strings.HasSuffix(*obj.Key, "/") {
+			if strings.HasSuffix(key, "/") {
 				continue
 			}
 
-			// 5. Download File from R2
-			fileObj, err := h.Client.GetObject(r.Context(), &s3.GetObjectInput{
-				Bucket: aws.String(h.Bucket),
-				Key:    obj.Key,
-			})
+			// 5. Download File from storage
+			body, size, err := h.Storage.Get(r.Context(), key)
 			if err != nil {
-				log.Printf("Failed to download %s: %v", *obj.Key, err)
+				log.Printf("Failed to download %s: %v", key, err)
 				continue
 			}
 
 			// 6. Create Tar Header
 			// We want the path inside the tar to be relative.
-			// If R2 key is "user_123/photos/summer/img.jpg" and we requested "photos",
+			// If the key is "user_123/photos/summer/img.jpg" and we requested "photos",
 			// We want the tar entry to be "photos/summer/img.jpg" or "summer/img.jpg".
 			// Let's strip the userID prefix to keep it clean.
-			relPath := strings.TrimPrefix(*obj.Key, userID+"/")
+			relPath := strings.TrimPrefix(key, userID+"/")
 
 			header := &tar.Header{
 				Name: relPath,
-				Size: *obj.Size,
+				Size: size,
 				Mode: 0644,
 			}
 
 			if err := tw.WriteHeader(header); err != nil {
 				log.Printf("Failed to write header for %s: %v", relPath, err)
-				fileObj.Body.Close()
+				body.Close()
 				continue
 			}
 
-			// 7. Stream content R2 -> Tar
-			if _, err := io.Copy(tw, fileObj.Body); err != nil {
+			// 7. Stream content storage -> Tar
+			if _, err := io.Copy(tw, body); err != nil {
 				log.Printf("Failed to copy body for %s: %v", relPath, err)
 			}
-			fileObj.Body.Close()
+			body.Close()
 			fileCount++
 		}
+
+		if nextMarker == "" {
+			break
+		}
+		marker = nextMarker
 	}
 
 	log.Printf("Downloaded directory '%s' (%d files) for user %s", dirName, fileCount, userID)
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/regysaputra/repoflow-backend/auth"
+	"github.com/regysaputra/repoflow-backend/storage"
+)
+
+const manifestFileName = ".repoflow-manifest.json"
+
+// manifestEntry describes one file tracked by a directory's manifest, as
+// recorded the last time it was pushed.
+type manifestEntry struct {
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	ETag   string `json:"etag,omitempty"`
+}
+
+// manifest is the per-file content catalogue incremental push-dir uses to
+// decide which files in a new tarball have actually changed. It's persisted
+// as a JSON object at "<prefix>.repoflow-manifest.json" alongside the files
+// it describes.
+type manifest struct {
+	Files map[string]manifestEntry `json:"files"`
+}
+
+// dirPrefix resolves a user ID and a "dir"/"name" query param into the
+// storage key prefix push-dir, pull-dir, and the manifest endpoints all
+// address a directory by.
+func dirPrefix(userID, dirName string) string {
+	clean := filepath.Clean(dirName)
+	if clean == "." || clean == "/" || clean == "" {
+		return userID + "/"
+	}
+	return userID + "/" + strings.Trim(clean, "/") + "/"
+}
+
+// loadManifest reads and decodes the manifest at prefix, returning an empty
+// manifest if none has been pushed yet.
+func loadManifest(ctx context.Context, s storage.Storage, prefix string) (*manifest, error) {
+	body, _, err := s.Get(ctx, prefix+manifestFileName)
+	if err != nil {
+		return &manifest{Files: map[string]manifestEntry{}}, nil
+	}
+	defer body.Close()
+
+	var m manifest
+	if err := json.NewDecoder(body).Decode(&m); err != nil {
+		return nil, err
+	}
+	if m.Files == nil {
+		m.Files = map[string]manifestEntry{}
+	}
+	return &m, nil
+}
+
+// saveManifest writes m back to "<prefix>.repoflow-manifest.json".
+func saveManifest(ctx context.Context, s storage.Storage, prefix string, m *manifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, prefix+manifestFileName, bytes.NewReader(data), int64(len(data)), nil)
+}
+
+// manifestHandler handles GET /manifest?dir=…, returning the manifest for a
+// directory so a client can diff its local files against what the server
+// already has before deciding what to push.
+func (h *FileHandler) manifestHandler(w http.ResponseWriter, r *http.Request) {
+	userID := auth.FromContext(r.Context()).UserID
+	prefix := dirPrefix(userID, r.URL.Query().Get("dir"))
+
+	m, err := loadManifest(r.Context(), h.Storage, prefix)
+	if err != nil {
+		log.Printf("Failed to load manifest for %s: %v", prefix, err)
+		SendJSON(w, http.StatusInternalServerError, Response{false, "Failed to load manifest"})
+		return
+	}
+
+	SendJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"files":   m.Files,
+	})
+}
+
+// pushDirCandidate is one entry in the negotiation body incremental
+// push-dir's first phase sends, describing a file as the client sees it.
+type pushDirCandidate struct {
+	RelPath string `json:"relPath"`
+	SHA256  string `json:"sha256"`
+	Size    int64  `json:"size"`
+}
+
+type negotiatePushDirRequest struct {
+	Files []pushDirCandidate `json:"files"`
+}
+
+// negotiatePushDir handles the JSON-bodied first phase of
+// POST /push-dir?mode=incremental: given the client's complete current file
+// list, it responds with the subset the server actually needs, so the
+// client can stream a tarball containing only those files.
+func (h *FileHandler) negotiatePushDir(w http.ResponseWriter, r *http.Request, prefix string) {
+	var req negotiatePushDirRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		SendJSON(w, http.StatusBadRequest, Response{false, "Invalid JSON body"})
+		return
+	}
+
+	m, err := loadManifest(r.Context(), h.Storage, prefix)
+	if err != nil {
+		log.Printf("Failed to load manifest for %s: %v", prefix, err)
+		SendJSON(w, http.StatusInternalServerError, Response{false, "Failed to load manifest"})
+		return
+	}
+
+	present := make(map[string]bool, len(req.Files))
+	var need []string
+	for _, f := range req.Files {
+		present[f.RelPath] = true
+		existing, ok := m.Files[f.RelPath]
+		if !ok || existing.SHA256 != f.SHA256 || existing.Size != f.Size {
+			need = append(need, f.RelPath)
+		}
+	}
+
+	// Reconcile the manifest against the client's complete current file
+	// list now: anything it still tracks that the client no longer has is
+	// dropped here, so a later ?prune=true can actually detect a file the
+	// client deleted instead of only ever seeing files that got re-uploaded.
+	for relPath := range m.Files {
+		if !present[relPath] {
+			delete(m.Files, relPath)
+		}
+	}
+	if err := saveManifest(r.Context(), h.Storage, prefix, m); err != nil {
+		log.Printf("Failed to update manifest for %s: %v", prefix, err)
+	}
+
+	SendJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"need":    need,
+	})
+}
+
+// pruneDir deletes every object under prefix whose relative path is absent
+// from m, backing push-dir's ?prune=true flag so files removed on the
+// client side stop lingering on the server.
+func (h *FileHandler) pruneDir(ctx context.Context, prefix string, m *manifest) {
+	marker := ""
+	for {
+		objects, nextMarker, err := h.Storage.List(ctx, prefix, marker, 1000)
+		if err != nil {
+			log.Printf("Prune: failed to list %s: %v", prefix, err)
+			return
+		}
+
+		for _, obj := range objects {
+			relPath := strings.TrimPrefix(obj.Key, prefix)
+			if relPath == "" || relPath == manifestFileName {
+				continue
+			}
+			if _, ok := m.Files[relPath]; ok {
+				continue
+			}
+			if err := h.Storage.Delete(ctx, obj.Key); err != nil {
+				log.Printf("Prune: failed to delete %s: %v", obj.Key, err)
+				continue
+			}
+			log.Printf("Prune: removed stale object %s", obj.Key)
+		}
+
+		if nextMarker == "" {
+			break
+		}
+		marker = nextMarker
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/regysaputra/repoflow-backend/auth"
+	"github.com/regysaputra/repoflow-backend/storage"
+)
+
+// MultipartHandler exposes the explicit CreateMultipartUpload/UploadPart/
+// Complete/Abort endpoints so clients can push files larger than pushHandler's
+// in-memory form caps and resume interrupted transfers. It only works
+// against a Storage backend that implements storage.MultipartStorage.
+type MultipartHandler struct {
+	Storage storage.MultipartStorage
+
+	mu      sync.Mutex
+	uploads map[string]uploadInfo // uploadID -> (owner, key), so later calls don't need to resend the key and can't be hijacked cross-tenant
+}
+
+// uploadInfo records who started an in-flight multipart upload and which
+// key it targets.
+type uploadInfo struct {
+	userID string
+	key    string
+}
+
+// NewMultipartHandler builds a MultipartHandler backed by s.
+func NewMultipartHandler(s storage.MultipartStorage) *MultipartHandler {
+	return &MultipartHandler{Storage: s, uploads: make(map[string]uploadInfo)}
+}
+
+// Create handles POST /uploads?file=<name>, starting a multipart upload and
+// returning its uploadId.
+func (h *MultipartHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := auth.FromContext(r.Context()).UserID
+
+	filename := r.URL.Query().Get("file")
+	if filename == "" {
+		SendJSON(w, http.StatusBadRequest, Response{false, "file parameter required"})
+		return
+	}
+	key := fmt.Sprintf("%s/%s", userID, filename)
+
+	uploadID, err := h.Storage.CreateMultipartUpload(r.Context(), key)
+	if err != nil {
+		SendJSON(w, http.StatusInternalServerError, Response{false, "Failed to create upload"})
+		return
+	}
+
+	h.mu.Lock()
+	h.uploads[uploadID] = uploadInfo{userID: userID, key: key}
+	h.mu.Unlock()
+
+	SendJSON(w, http.StatusOK, map[string]interface{}{
+		"success":  true,
+		"uploadId": uploadID,
+	})
+}
+
+// keyForUpload returns the key uploadID targets, but only if it was created
+// by userID — otherwise it's treated the same as an unknown upload, so one
+// tenant can't upload into, complete, or abort another tenant's transfer.
+func (h *MultipartHandler) keyForUpload(uploadID, userID string) (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	info, ok := h.uploads[uploadID]
+	if !ok || info.userID != userID {
+		return "", false
+	}
+	return info.key, true
+}
+
+// UploadPart handles PUT /uploads/{id}?partNumber=N, streaming the request
+// body in as one part.
+func (h *MultipartHandler) UploadPart(w http.ResponseWriter, r *http.Request) {
+	uploadID := chi.URLParam(r, "id")
+	userID := auth.FromContext(r.Context()).UserID
+	key, ok := h.keyForUpload(uploadID, userID)
+	if !ok {
+		SendJSON(w, http.StatusNotFound, Response{false, "Unknown upload"})
+		return
+	}
+
+	partNumber, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if err != nil || partNumber < 1 {
+		SendJSON(w, http.StatusBadRequest, Response{false, "partNumber query param required"})
+		return
+	}
+
+	etag, err := h.Storage.UploadPart(r.Context(), key, uploadID, int32(partNumber), r.Body, r.ContentLength)
+	if err != nil {
+		SendJSON(w, http.StatusInternalServerError, Response{false, "Failed to upload part"})
+		return
+	}
+
+	SendJSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"partNumber": partNumber,
+		"etag":       etag,
+	})
+}
+
+type completeUploadRequest struct {
+	Parts []storage.CompletedPart `json:"parts"`
+}
+
+// Complete handles POST /uploads/{id}/complete, assembling the uploaded
+// parts into the final object.
+func (h *MultipartHandler) Complete(w http.ResponseWriter, r *http.Request) {
+	uploadID := chi.URLParam(r, "id")
+	userID := auth.FromContext(r.Context()).UserID
+	key, ok := h.keyForUpload(uploadID, userID)
+	if !ok {
+		SendJSON(w, http.StatusNotFound, Response{false, "Unknown upload"})
+		return
+	}
+
+	var req completeUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Parts) == 0 {
+		SendJSON(w, http.StatusBadRequest, Response{false, "parts list required"})
+		return
+	}
+
+	if err := h.Storage.CompleteMultipartUpload(r.Context(), key, uploadID, req.Parts); err != nil {
+		SendJSON(w, http.StatusInternalServerError, Response{false, "Failed to complete upload"})
+		return
+	}
+
+	h.mu.Lock()
+	delete(h.uploads, uploadID)
+	h.mu.Unlock()
+
+	SendJSON(w, http.StatusOK, Response{true, fmt.Sprintf("Upload '%s' completed", key)})
+}
+
+// Abort handles DELETE /uploads/{id}, discarding any parts uploaded so far.
+func (h *MultipartHandler) Abort(w http.ResponseWriter, r *http.Request) {
+	uploadID := chi.URLParam(r, "id")
+	userID := auth.FromContext(r.Context()).UserID
+	key, ok := h.keyForUpload(uploadID, userID)
+	if !ok {
+		SendJSON(w, http.StatusNotFound, Response{false, "Unknown upload"})
+		return
+	}
+
+	if err := h.Storage.AbortMultipartUpload(r.Context(), key, uploadID); err != nil {
+		SendJSON(w, http.StatusInternalServerError, Response{false, "Failed to abort upload"})
+		return
+	}
+
+	h.mu.Lock()
+	delete(h.uploads, uploadID)
+	h.mu.Unlock()
+
+	SendJSON(w, http.StatusOK, Response{true, "Upload aborted"})
+}
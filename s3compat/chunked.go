@@ -0,0 +1,148 @@
+package s3compat
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// chunkedReader unwraps an "x-amz-content-sha256: STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+// body into its underlying bytes. Each frame on the wire looks like:
+//
+//	hex(chunk-size);chunk-signature=<sig>\r\n
+//	<chunk-size bytes of data>\r\n
+//
+// and the final, zero-length frame terminates the stream. Since the outer
+// request's payload hash for a streaming upload is just the
+// STREAMING-AWS4-HMAC-SHA256-PAYLOAD sentinel (not a real hash of the body),
+// the chunk-signature chain is the only thing binding the signature to the
+// actual bytes — so each chunk's signature is verified against the rolling
+// signature of the one before it as the stream is read, and a mismatch
+// fails the read.
+type chunkedReader struct {
+	src        *bufio.Reader
+	buf        *bytes.Reader
+	done       bool
+	err        error
+	signingKey []byte
+	amzDate    string
+	scope      string
+	priorSig   string
+}
+
+// newChunkedReader wraps r, verifying each chunk's "chunk-signature=" against
+// the rolling signature chain seeded by the outer request's Authorization
+// signature.
+func newChunkedReader(r io.Reader, signingKey []byte, amzDate, scope, seedSignature string) *chunkedReader {
+	return &chunkedReader{
+		src:        bufio.NewReader(r),
+		signingKey: signingKey,
+		amzDate:    amzDate,
+		scope:      scope,
+		priorSig:   seedSignature,
+	}
+}
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	if c.done {
+		return 0, io.EOF
+	}
+
+	if c.buf == nil || c.buf.Len() == 0 {
+		if err := c.nextChunk(); err != nil {
+			c.err = err
+			return 0, err
+		}
+		if c.done {
+			return 0, io.EOF
+		}
+	}
+
+	return c.buf.Read(p)
+}
+
+// nextChunk reads one "hex-size;chunk-signature=...\r\n<data>\r\n" frame,
+// verifies its signature against the rolling chain, and buffers its data
+// (or marks the stream done, for the terminal zero-length chunk).
+func (c *chunkedReader) nextChunk() error {
+	size, sig, err := c.readChunkHeader()
+	if err != nil {
+		return err
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(c.src, data); err != nil {
+		return err
+	}
+	if _, err := c.src.Discard(2); err != nil { // trailing \r\n
+		return err
+	}
+
+	expected := c.chunkSignature(data)
+	if !hmac.Equal([]byte(expected), []byte(sig)) {
+		return fmt.Errorf("chunk-signature mismatch")
+	}
+	c.priorSig = sig
+
+	if size == 0 {
+		c.done = true
+		return nil
+	}
+	c.buf = bytes.NewReader(data)
+	return nil
+}
+
+// readChunkHeader reads a "hex-size;chunk-signature=...\r\n" frame header
+// and returns the declared chunk size and signature.
+func (c *chunkedReader) readChunkHeader() (size int, sig string, err error) {
+	line, err := c.src.ReadString('\n')
+	if err != nil {
+		return 0, "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+
+	parts := strings.SplitN(line, ";", 2)
+	size64, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 16, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("malformed chunk size %q: %w", parts[0], err)
+	}
+	if len(parts) != 2 {
+		return 0, "", fmt.Errorf("missing chunk-signature in frame %q", line)
+	}
+
+	const sigPrefix = "chunk-signature="
+	sigField := strings.TrimSpace(parts[1])
+	if !strings.HasPrefix(sigField, sigPrefix) {
+		return 0, "", fmt.Errorf("malformed chunk-signature field %q", sigField)
+	}
+
+	return int(size64), strings.TrimPrefix(sigField, sigPrefix), nil
+}
+
+// chunkSignature computes the rolling per-chunk SigV4 streaming signature:
+//
+//	HMAC(signingKey, "AWS4-HMAC-SHA256-PAYLOAD\n" + amzDate + "\n" + scope +
+//	    "\n" + priorSignature + "\n" + hash("") + "\n" + hash(chunkData))
+func (c *chunkedReader) chunkSignature(data []byte) string {
+	sts := "AWS4-HMAC-SHA256-PAYLOAD\n" +
+		c.amzDate + "\n" +
+		c.scope + "\n" +
+		c.priorSig + "\n" +
+		sha256Hex(nil) + "\n" +
+		sha256Hex(data)
+	return hex.EncodeToString(hmacSHA256(c.signingKey, []byte(sts)))
+}
+
+// isStreamingPayload reports whether the request declares the streaming
+// signed-payload content encoding.
+func isStreamingPayload(contentSHA256 string) bool {
+	return contentSHA256 == streamingPayload
+}
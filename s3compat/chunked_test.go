@@ -0,0 +1,90 @@
+package s3compat
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// buildChunkedBody encodes chunks into the wire format chunkedReader expects,
+// computing each frame's chunk-signature by walking the same rolling chain
+// chunkedReader verifies against.
+func buildChunkedBody(signingKey []byte, amzDate, scope, seedSignature string, chunks [][]byte) []byte {
+	signer := &chunkedReader{signingKey: signingKey, amzDate: amzDate, scope: scope, priorSig: seedSignature}
+
+	var buf bytes.Buffer
+	for _, data := range chunks {
+		sig := signer.chunkSignature(data)
+		signer.priorSig = sig
+		fmt.Fprintf(&buf, "%x;chunk-signature=%s\r\n", len(data), sig)
+		buf.Write(data)
+		buf.WriteString("\r\n")
+	}
+
+	// Terminal zero-length chunk.
+	sig := signer.chunkSignature(nil)
+	fmt.Fprintf(&buf, "%x;chunk-signature=%s\r\n\r\n", 0, sig)
+
+	return buf.Bytes()
+}
+
+func TestChunkedReaderValidVector(t *testing.T) {
+	signingKey := []byte("test-signing-key")
+	amzDate := "20260726T000000Z"
+	scope := "20260726/us-east-1/s3/aws4_request"
+	seedSignature := "seedsignature0000000000000000000000000000000000000000000000"
+
+	chunks := [][]byte{[]byte("hello "), []byte("world")}
+	body := buildChunkedBody(signingKey, amzDate, scope, seedSignature, chunks)
+
+	r := newChunkedReader(bytes.NewReader(body), signingKey, amzDate, scope, seedSignature)
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("reading valid chunked body: %v", err)
+	}
+	if string(got) != "hello world" {
+		t.Errorf("got %q, want %q", got, "hello world")
+	}
+}
+
+func TestChunkedReaderTamperedChunkData(t *testing.T) {
+	signingKey := []byte("test-signing-key")
+	amzDate := "20260726T000000Z"
+	scope := "20260726/us-east-1/s3/aws4_request"
+	seedSignature := "seedsignature0000000000000000000000000000000000000000000000"
+
+	chunks := [][]byte{[]byte("hello "), []byte("world")}
+	body := buildChunkedBody(signingKey, amzDate, scope, seedSignature, chunks)
+
+	// Flip a byte in the first chunk's data without touching its declared
+	// signature, as an on-path tamper would.
+	idx := bytes.Index(body, []byte("hello "))
+	if idx < 0 {
+		t.Fatal("test setup: could not locate chunk data in encoded body")
+	}
+	body[idx] = 'H'
+
+	r := newChunkedReader(bytes.NewReader(body), signingKey, amzDate, scope, seedSignature)
+	if _, err := io.ReadAll(r); err == nil {
+		t.Fatal("ReadAll succeeded on a chunked body with tampered chunk data, want error")
+	}
+}
+
+func TestChunkedReaderWrongSeedSignature(t *testing.T) {
+	signingKey := []byte("test-signing-key")
+	amzDate := "20260726T000000Z"
+	scope := "20260726/us-east-1/s3/aws4_request"
+	seedSignature := "seedsignature0000000000000000000000000000000000000000000000"
+
+	chunks := [][]byte{[]byte("hello world")}
+	body := buildChunkedBody(signingKey, amzDate, scope, seedSignature, chunks)
+
+	// A reader seeded with a different signature than the one the client
+	// actually used (e.g. the Authorization header signature was itself
+	// forged) should fail the very first chunk.
+	r := newChunkedReader(bytes.NewReader(body), signingKey, amzDate, scope, "wrongseedsignature00000000000000000000000000000000000000000")
+	if _, err := io.ReadAll(r); err == nil {
+		t.Fatal("ReadAll succeeded with a forged seed signature, want error")
+	}
+}
@@ -0,0 +1,61 @@
+// Package s3compat exposes an S3-compatible HTTP API (SigV4-authenticated
+// GET/PUT/DELETE/HEAD on objects, ListObjectsV2) so standard S3 clients such
+// as aws-cli, boto3, and rclone can talk to this backend directly, while
+// writing into R2 under the same per-user key layout the FileHandler uses.
+package s3compat
+
+import (
+	"os"
+	"strings"
+)
+
+// CredentialStore resolves an AWS-style access key ID to the secret key used
+// to verify SigV4 signatures and the backend user ID that owns it.
+type CredentialStore interface {
+	Lookup(accessKeyID string) (secret string, userID string, ok bool)
+}
+
+type staticCredential struct {
+	secret string
+	userID string
+}
+
+// StaticCredentialStore is a fixed, in-memory CredentialStore loaded from an
+// env var. It exists so S3-style clients have something to authenticate
+// against until the auth subsystem can issue real per-user credentials;
+// like the X-User-ID header it replaces, it is a placeholder.
+type StaticCredentialStore struct {
+	creds map[string]staticCredential
+}
+
+// NewStaticCredentialStoreFromEnv parses S3_CREDENTIALS, a comma-separated
+// list of "accessKeyID:secretKey:userID" triples, e.g.
+//
+//	S3_CREDENTIALS="AKIAEXAMPLE1:supersecret1:alice,AKIAEXAMPLE2:supersecret2:bob"
+func NewStaticCredentialStoreFromEnv() *StaticCredentialStore {
+	store := &StaticCredentialStore{creds: make(map[string]staticCredential)}
+
+	raw := os.Getenv("S3_CREDENTIALS")
+	if raw == "" {
+		return store
+	}
+
+	for _, entry := range strings.Split(raw, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			continue
+		}
+		store.creds[parts[0]] = staticCredential{secret: parts[1], userID: parts[2]}
+	}
+
+	return store
+}
+
+// Lookup implements CredentialStore.
+func (s *StaticCredentialStore) Lookup(accessKeyID string) (string, string, bool) {
+	cred, ok := s.creds[accessKeyID]
+	if !ok {
+		return "", "", false
+	}
+	return cred.secret, cred.userID, true
+}
@@ -0,0 +1,61 @@
+package s3compat
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+// xmlError is the standard S3 error envelope clients parse to surface a
+// human-readable failure reason.
+type xmlError struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	Resource  string   `xml:"Resource,omitempty"`
+	RequestID string   `xml:"RequestId,omitempty"`
+}
+
+func writeXMLError(w http.ResponseWriter, status int, code, message, resource string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_ = xml.NewEncoder(w).Encode(xmlError{
+		Code:     code,
+		Message:  message,
+		Resource: resource,
+	})
+}
+
+func errAccessDenied(w http.ResponseWriter, resource, message string) {
+	writeXMLError(w, http.StatusForbidden, "AccessDenied", message, resource)
+}
+
+func errSignatureDoesNotMatch(w http.ResponseWriter, resource string) {
+	writeXMLError(w, http.StatusForbidden, "SignatureDoesNotMatch",
+		"The request signature we calculated does not match the signature you provided.", resource)
+}
+
+func errInvalidAccessKeyID(w http.ResponseWriter, resource string) {
+	writeXMLError(w, http.StatusForbidden, "InvalidAccessKeyId",
+		"The AWS Access Key Id you provided does not exist in our records.", resource)
+}
+
+func errRequestTimeTooSkewed(w http.ResponseWriter, resource string) {
+	writeXMLError(w, http.StatusForbidden, "RequestTimeTooSkewed",
+		"The difference between the request time and the current time is too large.", resource)
+}
+
+func errNoSuchKey(w http.ResponseWriter, resource string) {
+	writeXMLError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.", resource)
+}
+
+func errNoSuchBucket(w http.ResponseWriter, resource string) {
+	writeXMLError(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist.", resource)
+}
+
+func errInternal(w http.ResponseWriter, resource string) {
+	writeXMLError(w, http.StatusInternalServerError, "InternalError", "We encountered an internal error. Please try again.", resource)
+}
+
+func errMethodNotAllowed(w http.ResponseWriter, resource string) {
+	writeXMLError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource.", resource)
+}
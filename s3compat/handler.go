@@ -0,0 +1,348 @@
+package s3compat
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Handler serves an S3-compatible API (path-style bucket/key addressing,
+// SigV4 auth) on top of an R2 bucket, using the same per-user key layout as
+// the native /push, /pull, /list routes. Every object key is namespaced under
+// the caller's user ID, so `aws s3 cp foo s3://any-bucket/foo.txt` lands at
+// the R2 key `<userID>/foo.txt`, the key `POST /push` writes when the native
+// routes run on the R2 Storage backend.
+type Handler struct {
+	Client      *s3.Client
+	Bucket      string
+	Credentials CredentialStore
+}
+
+// NewHandler builds an S3-compatible Handler backed by client/bucket, with
+// access keys resolved through creds.
+func NewHandler(client *s3.Client, bucket string, creds CredentialStore) *Handler {
+	return &Handler{Client: client, Bucket: bucket, Credentials: creds}
+}
+
+// ServeHTTP authenticates the request with SigV4, then dispatches to the
+// matching S3 operation based on method and path shape.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	bucket, key := splitBucketKey(r.URL.Path)
+	if bucket == "" {
+		errNoSuchBucket(w, r.URL.Path)
+		return
+	}
+
+	switch {
+	case key == "" && r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		h.listObjectsV2(w, r, userID)
+	case key != "" && r.Method == http.MethodPut:
+		h.putObject(w, r, userID, key)
+	case key != "" && r.Method == http.MethodGet:
+		h.getObject(w, r, userID, key)
+	case key != "" && r.Method == http.MethodHead:
+		h.headObject(w, r, userID, key)
+	case key != "" && r.Method == http.MethodDelete:
+		h.deleteObject(w, r, userID, key)
+	default:
+		errMethodNotAllowed(w, r.URL.Path)
+	}
+}
+
+// splitBucketKey splits a path-style "/{bucket}/{key...}" URL path.
+func splitBucketKey(path string) (bucket, key string) {
+	trimmed := strings.TrimPrefix(path, "/")
+	parts := strings.SplitN(trimmed, "/", 2)
+	bucket = parts[0]
+	if len(parts) == 2 {
+		key = parts[1]
+	}
+	return bucket, key
+}
+
+// authenticate verifies the request's SigV4 (or rejects legacy V2)
+// Authorization header and returns the backend user ID it maps to.
+func (h *Handler) authenticate(w http.ResponseWriter, r *http.Request) (string, bool) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		errAccessDenied(w, r.URL.Path, "Request is missing Authorization header")
+		return "", false
+	}
+
+	if legacyV2(authHeader) {
+		errSignatureDoesNotMatch(w, r.URL.Path)
+		return "", false
+	}
+
+	sig, err := parseAuthorizationHeader(authHeader)
+	if err != nil {
+		errAccessDenied(w, r.URL.Path, err.Error())
+		return "", false
+	}
+
+	secret, userID, ok := h.Credentials.Lookup(sig.AccessKeyID)
+	if !ok {
+		errInvalidAccessKeyID(w, r.URL.Path)
+		return "", false
+	}
+
+	amzDate, err := requestDate(r)
+	if err != nil {
+		errRequestTimeTooSkewed(w, r.URL.Path)
+		return "", false
+	}
+
+	contentSHA256 := r.Header.Get("x-amz-content-sha256")
+
+	dateStamp := amzDate.UTC().Format(dateStampLayout)
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", dateStamp, sig.Region, sig.Service)
+	key := signingKey(secret, dateStamp, sig.Region, sig.Service)
+
+	payloadHash, body, err := hashPayload(w, r, contentSHA256, key, amzDate, scope, sig.Signature)
+	if err != nil {
+		errAccessDenied(w, r.URL.Path, err.Error())
+		return "", false
+	}
+	r.Body = body
+
+	if !verifySignature(r, sig, secret, payloadHash, amzDate) {
+		errSignatureDoesNotMatch(w, r.URL.Path)
+		return "", false
+	}
+
+	return userID, true
+}
+
+// maxBufferedPayload bounds how much of an ordinary (non-streaming,
+// non-UNSIGNED-PAYLOAD) request body hashPayload will buffer in memory to
+// compute its SigV4 hash, mirroring the caps pushHandler/pushDirHandler
+// apply to their own multipart bodies. A valid access-key-id is a
+// semi-public identifier, not proof the request is actually signed
+// correctly, so this path must be reachable by an unauthenticated caller
+// without risking unbounded memory use.
+const maxBufferedPayload = 500 << 20 // 500MB
+
+// hashPayload returns the hex SHA-256 used in the canonical request, and a
+// body reader the caller should use in place of r.Body. UNSIGNED-PAYLOAD
+// requests get the sentinel back unmodified; streaming-signed requests get
+// a chunkedReader that verifies each chunk's rolling signature as it's read
+// and unwraps the frames to recover the plaintext body; ordinary requests
+// are buffered (capped at maxBufferedPayload) to hash them up front, which
+// SigV4 requires.
+func hashPayload(w http.ResponseWriter, r *http.Request, contentSHA256 string, signingKey []byte, amzDate time.Time, scope, seedSignature string) (string, io.ReadCloser, error) {
+	switch {
+	case contentSHA256 == "":
+		return unsignedPayload, r.Body, nil
+	case contentSHA256 == unsignedPayload:
+		return contentSHA256, r.Body, nil
+	case isStreamingPayload(contentSHA256):
+		amzDateStr := amzDate.UTC().Format(amzDateLayout)
+		body := io.NopCloser(newChunkedReader(r.Body, signingKey, amzDateStr, scope, seedSignature))
+		return contentSHA256, body, nil
+	default:
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBufferedPayload))
+		if err != nil {
+			return "", nil, err
+		}
+		return sha256Hex(data), io.NopCloser(strings.NewReader(string(data))), nil
+	}
+}
+
+func objectKey(userID, key string) string {
+	return userID + "/" + key
+}
+
+func (h *Handler) putObject(w http.ResponseWriter, r *http.Request, userID, key string) {
+	// For STREAMING-AWS4-HMAC-SHA256-PAYLOAD uploads, r.ContentLength is the
+	// *encoded* size (chunk-size/signature framing included), but Body here
+	// is already the unwrapped chunkedReader, which yields far fewer bytes.
+	// Clients that chunk-sign (the common default for aws-cli/boto3) send
+	// the real, decoded size in X-Amz-Decoded-Content-Length instead.
+	var contentLength *int64
+	if decoded := r.Header.Get("X-Amz-Decoded-Content-Length"); decoded != "" {
+		if n, err := strconv.ParseInt(decoded, 10, 64); err == nil {
+			contentLength = aws.Int64(n)
+		}
+	} else if cl := r.ContentLength; cl >= 0 {
+		contentLength = aws.Int64(cl)
+	}
+
+	_, err := h.Client.PutObject(r.Context(), &s3.PutObjectInput{
+		Bucket:        aws.String(h.Bucket),
+		Key:           aws.String(objectKey(userID, key)),
+		Body:          r.Body,
+		ContentLength: contentLength,
+		Metadata: map[string]string{
+			"owner-id": userID,
+		},
+	})
+	if err != nil {
+		log.Printf("s3compat: PutObject failed for %s: %v", key, err)
+		errInternal(w, r.URL.Path)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) getObject(w http.ResponseWriter, r *http.Request, userID, key string) {
+	out, err := h.Client.GetObject(r.Context(), &s3.GetObjectInput{
+		Bucket: aws.String(h.Bucket),
+		Key:    aws.String(objectKey(userID, key)),
+	})
+	if err != nil {
+		errNoSuchKey(w, r.URL.Path)
+		return
+	}
+	defer out.Body.Close()
+
+	if out.ContentLength != nil {
+		w.Header().Set("Content-Length", strconv.FormatInt(*out.ContentLength, 10))
+	}
+	if out.ETag != nil {
+		w.Header().Set("ETag", *out.ETag)
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, out.Body); err != nil {
+		log.Printf("s3compat: stream error for %s: %v", key, err)
+	}
+}
+
+func (h *Handler) headObject(w http.ResponseWriter, r *http.Request, userID, key string) {
+	out, err := h.Client.HeadObject(r.Context(), &s3.HeadObjectInput{
+		Bucket: aws.String(h.Bucket),
+		Key:    aws.String(objectKey(userID, key)),
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if out.ContentLength != nil {
+		w.Header().Set("Content-Length", strconv.FormatInt(*out.ContentLength, 10))
+	}
+	if out.ETag != nil {
+		w.Header().Set("ETag", *out.ETag)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) deleteObject(w http.ResponseWriter, r *http.Request, userID, key string) {
+	_, err := h.Client.DeleteObject(r.Context(), &s3.DeleteObjectInput{
+		Bucket: aws.String(h.Bucket),
+		Key:    aws.String(objectKey(userID, key)),
+	})
+	if err != nil {
+		log.Printf("s3compat: DeleteObject failed for %s: %v", key, err)
+		errInternal(w, r.URL.Path)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listObjectsV2Result mirrors the subset of the S3 ListObjectsV2 XML
+// response shape that clients rely on.
+type listObjectsV2Result struct {
+	XMLName       xml.Name               `xml:"ListBucketResult"`
+	Name          string                 `xml:"Name"`
+	Prefix        string                 `xml:"Prefix"`
+	KeyCount      int                    `xml:"KeyCount"`
+	MaxKeys       int                    `xml:"MaxKeys"`
+	IsTruncated   bool                   `xml:"IsTruncated"`
+	Contents      []listObjectsV2Content `xml:"Contents"`
+	NextContToken string                 `xml:"NextContinuationToken,omitempty"`
+}
+
+type listObjectsV2Content struct {
+	Key          string `xml:"Key"`
+	Size         int64  `xml:"Size"`
+	ETag         string `xml:"ETag"`
+	LastModified string `xml:"LastModified"`
+}
+
+func (h *Handler) listObjectsV2(w http.ResponseWriter, r *http.Request, userID string) {
+	q := r.URL.Query()
+	userPrefix := userID + "/"
+	prefix := userPrefix + q.Get("prefix")
+
+	maxKeys := int32(1000)
+	if raw := q.Get("max-keys"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			maxKeys = int32(n)
+		}
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(h.Bucket),
+		Prefix:  aws.String(prefix),
+		MaxKeys: aws.Int32(maxKeys),
+	}
+	if token := q.Get("continuation-token"); token != "" {
+		input.ContinuationToken = aws.String(token)
+	}
+
+	out, err := h.Client.ListObjectsV2(r.Context(), input)
+	if err != nil {
+		log.Printf("s3compat: ListObjectsV2 failed: %v", err)
+		errInternal(w, r.URL.Path)
+		return
+	}
+
+	result := listObjectsV2Result{
+		Name:        bucketNameFromPath(r.URL.Path),
+		Prefix:      q.Get("prefix"),
+		MaxKeys:     int(maxKeys),
+		IsTruncated: aws.ToBool(out.IsTruncated),
+	}
+	if out.NextContinuationToken != nil {
+		result.NextContToken = *out.NextContinuationToken
+	}
+
+	contents := make([]listObjectsV2Content, 0, len(out.Contents))
+	for _, obj := range out.Contents {
+		cleanKey := strings.TrimPrefix(aws.ToString(obj.Key), userPrefix)
+		if cleanKey == "" {
+			continue
+		}
+		contents = append(contents, listObjectsV2Content{
+			Key:          cleanKey,
+			Size:         aws.ToInt64(obj.Size),
+			ETag:         aws.ToString(obj.ETag),
+			LastModified: formatLastModified(obj),
+		})
+	}
+	sort.Slice(contents, func(i, j int) bool { return contents[i].Key < contents[j].Key })
+	result.Contents = contents
+	result.KeyCount = len(contents)
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func formatLastModified(obj types.Object) string {
+	if obj.LastModified == nil {
+		return ""
+	}
+	return obj.LastModified.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+func bucketNameFromPath(path string) string {
+	bucket, _ := splitBucketKey(path)
+	return bucket
+}
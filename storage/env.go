@@ -0,0 +1,14 @@
+package storage
+
+import "os"
+
+func getenv(key string) string {
+	return os.Getenv(key)
+}
+
+func getenvDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const metaSuffix = ".repoflow-meta.json"
+
+// LocalFSStorage stores objects as plain files under a root directory, for
+// offline development and self-hosting without any cloud storage account.
+// Object metadata (set via Put) is kept in a sidecar "<key>.repoflow-meta.json"
+// file next to the data, since the filesystem has no notion of it.
+type LocalFSStorage struct {
+	root string
+}
+
+// NewLocalFSStorage builds a LocalFSStorage rooted at LOCAL_STORAGE_DIR
+// (defaulting to "./data"), creating the directory if it doesn't exist.
+func NewLocalFSStorage() (*LocalFSStorage, error) {
+	root := getenvDefault("LOCAL_STORAGE_DIR", "./data")
+
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("creating local storage dir %q: %w", root, err)
+	}
+
+	return &LocalFSStorage{root: root}, nil
+}
+
+// path resolves a storage key to an on-disk path, rejecting any key that
+// would escape root.
+func (l *LocalFSStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	full := filepath.Join(l.root, clean)
+	if !strings.HasPrefix(full, filepath.Clean(l.root)+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	return full, nil
+}
+
+func (l *LocalFSStorage) Put(ctx context.Context, key string, body io.Reader, size int64, meta map[string]string) error {
+	full, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(full)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, body); err != nil {
+		return err
+	}
+
+	if len(meta) == 0 {
+		return nil
+	}
+	metaBytes, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(full+metaSuffix, metaBytes, 0o644)
+}
+
+func (l *LocalFSStorage) Get(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	full, err := l.path(key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	f, err := os.Open(full)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+
+	return f, info.Size(), nil
+}
+
+// GetRange implements storage.RangeStorage.
+func (l *LocalFSStorage) GetRange(ctx context.Context, key, rangeSpec string) (io.ReadCloser, string, error) {
+	full, err := l.path(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	f, err := os.Open(full)
+	if err != nil {
+		return nil, "", err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, "", err
+	}
+	size := info.Size()
+
+	start, end, err := parseByteRange(rangeSpec, size)
+	if err != nil {
+		f.Close()
+		return nil, "", err
+	}
+
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		f.Close()
+		return nil, "", err
+	}
+
+	contentRange := fmt.Sprintf("bytes %d-%d/%d", start, end, size)
+	return rangeReadCloser{Reader: io.LimitReader(f, end-start+1), Closer: f}, contentRange, nil
+}
+
+// rangeReadCloser pairs a bounded Reader (the io.LimitReader over a range of
+// the file) with the underlying file's Closer.
+type rangeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// parseByteRange parses a single "bytes=start-end" HTTP Range header value
+// (including the open-ended "bytes=start-" and suffix "bytes=-N" forms)
+// against an object of the given size.
+func parseByteRange(rangeSpec string, size int64) (start, end int64, err error) {
+	spec := strings.TrimPrefix(rangeSpec, "bytes=")
+	if spec == rangeSpec {
+		return 0, 0, fmt.Errorf("unsupported range unit in %q", rangeSpec)
+	}
+
+	parts := strings.SplitN(spec, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("malformed range %q", rangeSpec)
+	}
+
+	if parts[0] == "" {
+		// Suffix range: last N bytes.
+		n, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("malformed range %q: %w", rangeSpec, err)
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, nil
+	}
+
+	start, err = strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed range %q: %w", rangeSpec, err)
+	}
+
+	if parts[1] == "" {
+		return start, size - 1, nil
+	}
+
+	end, err = strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed range %q: %w", rangeSpec, err)
+	}
+	if end >= size {
+		end = size - 1
+	}
+	if start > end {
+		return 0, 0, fmt.Errorf("invalid range %q for size %d", rangeSpec, size)
+	}
+
+	return start, end, nil
+}
+
+func (l *LocalFSStorage) List(ctx context.Context, prefix, marker string, max int) ([]ObjectInfo, string, error) {
+	var objects []ObjectInfo
+
+	err := filepath.Walk(l.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasSuffix(path, metaSuffix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(l.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, ObjectInfo{
+				Key:          key,
+				Size:         info.Size(),
+				LastModified: info.ModTime(),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+
+	start := 0
+	if marker != "" {
+		start = sort.Search(len(objects), func(i int) bool { return objects[i].Key >= marker })
+		if start < len(objects) && objects[start].Key == marker {
+			start++
+		}
+	}
+	objects = objects[start:]
+
+	if max > 0 && len(objects) > max {
+		return objects[:max], objects[max-1].Key, nil
+	}
+	return objects, "", nil
+}
+
+func (l *LocalFSStorage) Delete(ctx context.Context, key string) error {
+	full, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(full); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	_ = os.Remove(full + metaSuffix)
+	return nil
+}
+
+func (l *LocalFSStorage) Head(ctx context.Context, key string) (int64, map[string]string, error) {
+	full, err := l.path(key)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	info, err := os.Stat(full)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	meta := map[string]string{}
+	if metaBytes, err := os.ReadFile(full + metaSuffix); err == nil {
+		_ = json.Unmarshal(metaBytes, &meta)
+	}
+
+	return info.Size(), meta, nil
+}
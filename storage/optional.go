@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"context"
+	"io"
+)
+
+// RangeStorage is implemented by backends that can serve a byte-range
+// subset of an object without reading the whole thing, so pullHandler can
+// offer HTTP Range support (resumable downloads, video seeking) when the
+// backend allows it.
+type RangeStorage interface {
+	// GetRange returns the body for rangeSpec (an HTTP "bytes=start-end"
+	// Range header value) and the Content-Range header value to echo back
+	// to the client.
+	GetRange(ctx context.Context, key, rangeSpec string) (body io.ReadCloser, contentRange string, err error)
+}
+
+// CompletedPart identifies one successfully uploaded part of a multipart
+// upload by its part number and the ETag MultipartStorage.UploadPart
+// returned for it.
+type CompletedPart struct {
+	PartNumber int32
+	ETag       string
+}
+
+// MultipartStorage is implemented by backends that support S3-style
+// multipart upload, letting clients push large files as a series of
+// resumable part uploads instead of one long-lived request.
+type MultipartStorage interface {
+	CreateMultipartUpload(ctx context.Context, key string) (uploadID string, err error)
+	UploadPart(ctx context.Context, key, uploadID string, partNumber int32, body io.Reader, size int64) (etag string, err error)
+	CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error
+	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
+}
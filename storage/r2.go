@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// R2Storage stores objects in a Cloudflare R2 bucket via the S3-compatible
+// API aws-sdk-go-v2 speaks to any endpoint. Its Put/Get/List/... methods are
+// provided by the embedded s3Backend, which R2 and generic S3 share since
+// they speak the identical wire protocol.
+type R2Storage struct {
+	s3Backend
+}
+
+// NewR2Storage builds an R2Storage from R2_ACCOUNT_ID, R2_ACCESS_KEY,
+// R2_SECRET_KEY, and R2_BUCKET. All four are required.
+func NewR2Storage(ctx context.Context) (*R2Storage, error) {
+	accountID := getenv("R2_ACCOUNT_ID")
+	accessKey := getenv("R2_ACCESS_KEY")
+	secretKey := getenv("R2_SECRET_KEY")
+	bucket := getenv("R2_BUCKET")
+
+	if accountID == "" || accessKey == "" || secretKey == "" || bucket == "" {
+		return nil, fmt.Errorf("R2_ACCOUNT_ID, R2_ACCESS_KEY, R2_SECRET_KEY, and R2_BUCKET are required")
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx,
+		config.WithRegion("auto"),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(fmt.Sprintf("https://%s.r2.cloudflarestorage.com", accountID))
+	})
+
+	return &R2Storage{s3Backend{client: client, bucket: bucket}}, nil
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Storage stores objects against an arbitrary S3-compatible endpoint
+// (MinIO, AWS itself, Backblaze B2, …), so deployments aren't locked to
+// Cloudflare R2. Its Put/Get/List/... methods are provided by the embedded
+// s3Backend, which R2 and generic S3 share since they speak the identical
+// wire protocol.
+type S3Storage struct {
+	s3Backend
+}
+
+// NewS3Storage builds an S3Storage from S3_ENDPOINT, S3_ACCESS_KEY,
+// S3_SECRET_KEY, and S3_BUCKET. S3_REGION defaults to "us-east-1", and
+// S3_FORCE_PATH_STYLE=true enables path-style addressing for backends (like
+// MinIO) that don't support virtual-hosted buckets.
+func NewS3Storage(ctx context.Context) (*S3Storage, error) {
+	endpoint := getenv("S3_ENDPOINT")
+	accessKey := getenv("S3_ACCESS_KEY")
+	secretKey := getenv("S3_SECRET_KEY")
+	bucket := getenv("S3_BUCKET")
+	region := getenvDefault("S3_REGION", "us-east-1")
+
+	if endpoint == "" || accessKey == "" || secretKey == "" || bucket == "" {
+		return nil, fmt.Errorf("S3_ENDPOINT, S3_ACCESS_KEY, S3_SECRET_KEY, and S3_BUCKET are required")
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx,
+		config.WithRegion(region),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+		o.UsePathStyle = getenv("S3_FORCE_PATH_STYLE") == "true"
+	})
+
+	return &S3Storage{s3Backend{client: client, bucket: bucket}}, nil
+}
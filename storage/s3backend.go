@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"context"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// s3Backend implements Storage, RangeStorage, and MultipartStorage against
+// any S3-compatible *s3.Client/bucket pair. R2Storage and S3Storage embed it
+// and differ only in how they build that client, since Cloudflare R2 and a
+// generic S3-compatible endpoint speak the identical wire protocol.
+type s3Backend struct {
+	client *s3.Client
+	bucket string
+}
+
+func (b *s3Backend) Put(ctx context.Context, key string, body io.Reader, size int64, meta map[string]string) error {
+	_, err := b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(b.bucket),
+		Key:           aws.String(key),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+		Metadata:      meta,
+	})
+	return err
+}
+
+func (b *s3Backend) Get(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	out, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return out.Body, aws.ToInt64(out.ContentLength), nil
+}
+
+func (b *s3Backend) List(ctx context.Context, prefix, marker string, max int) ([]ObjectInfo, string, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.bucket),
+		Prefix: aws.String(prefix),
+	}
+	if max > 0 {
+		input.MaxKeys = aws.Int32(int32(max))
+	}
+	if marker != "" {
+		input.ContinuationToken = aws.String(marker)
+	}
+
+	out, err := b.client.ListObjectsV2(ctx, input)
+	if err != nil {
+		return nil, "", err
+	}
+
+	objects := make([]ObjectInfo, 0, len(out.Contents))
+	for _, obj := range out.Contents {
+		info := ObjectInfo{
+			Key:  aws.ToString(obj.Key),
+			Size: aws.ToInt64(obj.Size),
+			ETag: aws.ToString(obj.ETag),
+		}
+		if obj.LastModified != nil {
+			info.LastModified = *obj.LastModified
+		}
+		objects = append(objects, info)
+	}
+
+	return objects, aws.ToString(out.NextContinuationToken), nil
+}
+
+func (b *s3Backend) Delete(ctx context.Context, key string) error {
+	_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func (b *s3Backend) Head(ctx context.Context, key string) (int64, map[string]string, error) {
+	out, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return aws.ToInt64(out.ContentLength), out.Metadata, nil
+}
+
+// GetRange implements storage.RangeStorage.
+func (b *s3Backend) GetRange(ctx context.Context, key, rangeSpec string) (io.ReadCloser, string, error) {
+	out, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+		Range:  aws.String(rangeSpec),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return out.Body, aws.ToString(out.ContentRange), nil
+}
+
+// CreateMultipartUpload implements storage.MultipartStorage.
+func (b *s3Backend) CreateMultipartUpload(ctx context.Context, key string) (string, error) {
+	out, err := b.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(out.UploadId), nil
+}
+
+// UploadPart implements storage.MultipartStorage.
+func (b *s3Backend) UploadPart(ctx context.Context, key, uploadID string, partNumber int32, body io.Reader, size int64) (string, error) {
+	out, err := b.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(b.bucket),
+		Key:           aws.String(key),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(partNumber),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(out.ETag), nil
+}
+
+// CompleteMultipartUpload implements storage.MultipartStorage.
+func (b *s3Backend) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error {
+	completed := make([]types.CompletedPart, len(parts))
+	for i, p := range parts {
+		completed[i] = types.CompletedPart{PartNumber: aws.Int32(p.PartNumber), ETag: aws.String(p.ETag)}
+	}
+
+	_, err := b.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(b.bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	return err
+}
+
+// AbortMultipartUpload implements storage.MultipartStorage.
+func (b *s3Backend) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	_, err := b.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(b.bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	return err
+}
@@ -0,0 +1,44 @@
+// Package storage defines the backend-agnostic object storage interface
+// FileHandler is built against, plus the concrete backends (Cloudflare R2,
+// generic S3-compatible endpoints, and the local filesystem) that implement
+// it.
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// ObjectInfo describes one key returned by Storage.List.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ETag         string
+	LastModified time.Time
+}
+
+// Storage is implemented by every object storage backend FileHandler can be
+// wired up against. All methods take fully-qualified keys (e.g.
+// "<userID>/<filename>"); callers own the keyspace convention.
+type Storage interface {
+	// Put uploads size bytes read from r to key, attaching meta as
+	// provider-specific object metadata.
+	Put(ctx context.Context, key string, r io.Reader, size int64, meta map[string]string) error
+
+	// Get returns a reader for the object at key and its size in bytes.
+	// The caller is responsible for closing the returned ReadCloser.
+	Get(ctx context.Context, key string) (io.ReadCloser, int64, error)
+
+	// List returns up to max objects under prefix, resuming after marker
+	// (pass "" for the first page). nextMarker is "" once there are no
+	// further pages.
+	List(ctx context.Context, prefix, marker string, max int) (objects []ObjectInfo, nextMarker string, err error)
+
+	// Delete removes the object at key.
+	Delete(ctx context.Context, key string) error
+
+	// Head returns the size and metadata of the object at key without
+	// fetching its body.
+	Head(ctx context.Context, key string) (size int64, meta map[string]string, err error)
+}